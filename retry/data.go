@@ -0,0 +1,57 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package retry
+
+import "context"
+
+// RetryableFuncWithData represents a function that can be retried and
+// returns a value alongside its error.
+type RetryableFuncWithData[T any] func() (T, error)
+
+// RetryableFuncWithContextAndData represents a function that can be
+// retried, accepts a context, and returns a value alongside its error.
+type RetryableFuncWithContextAndData[T any] func(context.Context) (T, error)
+
+// RetryWithData runs f under r's retry policy and returns the value
+// produced by the last successful attempt alongside the error.
+//
+// Go interfaces cannot have type-parameterized methods, so RetryWithData
+// is a standalone generic function rather than a method on Retryer.
+func RetryWithData[T any](r Retryer, f RetryableFuncWithData[T]) (T, error) {
+	var result T
+
+	err := r.Retry(func() error {
+		value, err := f()
+		if err != nil {
+			return err
+		}
+
+		result = value
+
+		return nil
+	})
+
+	return result, err
+}
+
+// RetryWithContextAndData runs f under r's retry policy, passing ctx
+// through to every attempt via r.RetryWithContext, and returns the value
+// produced by the last successful attempt alongside the error.
+func RetryWithContextAndData[T any](ctx context.Context, r Retryer, f RetryableFuncWithContextAndData[T]) (T, error) {
+	var result T
+
+	err := r.RetryWithContext(ctx, func(ctx context.Context) error {
+		value, err := f(ctx)
+		if err != nil {
+			return err
+		}
+
+		result = value
+
+		return nil
+	})
+
+	return result, err
+}
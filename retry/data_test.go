@@ -0,0 +1,73 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//nolint:testpackage
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_RetryWithData(t *testing.T) {
+	r := NewRetryer(2*time.Second, NewConstantTicker(NewDefaultOptions()), &Options{})
+
+	var calls int
+
+	value, err := RetryWithData(r, func() (int, error) {
+		calls++
+
+		if calls < 2 {
+			return 0, ExpectedError(errors.New("not yet"))
+		}
+
+		return 42, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if value != 42 {
+		t.Fatalf("expected 42, got %d", value)
+	}
+}
+
+func Test_RetryWithContextAndData(t *testing.T) {
+	r := NewRetryer(2*time.Second, NewConstantTicker(NewDefaultOptions()), &Options{})
+
+	value, err := RetryWithContextAndData(context.Background(), r, func(context.Context) (string, error) {
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if value != "ok" {
+		t.Fatalf("expected %q, got %q", "ok", value)
+	}
+}
+
+func Test_RetryWithContextAndData_CanceledContext(t *testing.T) {
+	r := NewRetryer(2*time.Second, NewConstantTicker(NewDefaultOptions()), &Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int
+
+	_, err := RetryWithContextAndData(ctx, r, func(context.Context) (string, error) {
+		calls++
+
+		return "", ExpectedError(errors.New("should not be retried"))
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if calls != 0 {
+		t.Fatalf("expected f not to be called once ctx was already canceled, got %d calls", calls)
+	}
+}
@@ -0,0 +1,181 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package httpretry wraps an *http.Client with a retry.Retryer, honoring
+// Retry-After response headers and resetting request bodies between
+// attempts.
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/siderolabs/go-retry/retry"
+)
+
+// RetryableStatus reports whether resp's status code should be retried.
+type RetryableStatus func(resp *http.Response) bool
+
+// DefaultRetryableStatus retries on 408, 429, 500, 502, 503 and 504, which
+// covers the common transient failure modes of well-behaved HTTP servers.
+func DefaultRetryableStatus(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// RetryableError reports whether err, returned by the underlying
+// http.Client.Do, should be retried.
+type RetryableError func(err error) bool
+
+// DefaultRetryableError retries net.Errors that report themselves as
+// timeouts, which covers the common transient failure mode of a slow or
+// unresponsive server.
+func DefaultRetryableError(err error) bool {
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// Client wraps an *http.Client, retrying requests according to a
+// retry.Retryer.
+type Client struct {
+	client          *http.Client
+	retryer         retry.Retryer
+	retryableStatus RetryableStatus
+	retryableError  RetryableError
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithRetryableStatus overrides the predicate used to decide whether a
+// response status code should be retried.
+func WithRetryableStatus(f RetryableStatus) Option {
+	return func(c *Client) {
+		c.retryableStatus = f
+	}
+}
+
+// WithRetryableError overrides the predicate used to decide whether an
+// error returned by the underlying http.Client.Do (as opposed to an HTTP
+// response status) should be retried.
+func WithRetryableError(f RetryableError) Option {
+	return func(c *Client) {
+		c.retryableError = f
+	}
+}
+
+// New wraps client, retrying requests according to retryer's policy.
+func New(client *http.Client, retryer retry.Retryer, opts ...Option) *Client {
+	c := &Client{
+		client:          client,
+		retryer:         retryer,
+		retryableStatus: DefaultRetryableStatus,
+		retryableError:  DefaultRetryableError,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Do sends req, retrying according to the Client's Retryer. Errors
+// accepted by the Client's RetryableError and responses whose status is
+// accepted by its RetryableStatus are treated as expected and retried;
+// anything else is fatal. If req has a GetBody (set automatically by
+// http.NewRequest for common body types), the body is reset before every
+// attempt so POSTs are safely retryable.
+//
+// If a retryable response carries a Retry-After header, the indicated
+// duration overrides the underlying Retryer's own backoff for the next
+// attempt, rather than being added on top of it.
+func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return retry.RetryWithContextAndData(ctx, c.retryer, func(ctx context.Context) (*http.Response, error) {
+		attempt := req.Clone(ctx)
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, retry.UnexpectedError(err)
+			}
+
+			attempt.Body = body
+		}
+
+		resp, err := c.client.Do(attempt)
+		if err != nil {
+			if c.retryableError(err) {
+				return nil, retry.ExpectedError(err)
+			}
+
+			return nil, retry.UnexpectedError(err)
+		}
+
+		if resp.StatusCode < 400 {
+			return resp, nil
+		}
+
+		retryable := c.retryableStatus(resp)
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		statusErr := fmt.Errorf("retryable status: %s", resp.Status)
+
+		switch {
+		case !retryable:
+			return nil, retry.UnexpectedError(fmt.Errorf("non-retryable status: %s", resp.Status))
+		case retryAfter > 0:
+			return nil, retry.ExpectedErrorAfter(statusErr, retryAfter)
+		default:
+			return nil, retry.ExpectedError(statusErr)
+		}
+	})
+}
+
+// parseRetryAfter parses a Retry-After header value in either the
+// delta-seconds or HTTP-date form, returning 0 if v is empty, invalid, or
+// already in the past.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+
+		return time.Duration(secs) * time.Second
+	}
+
+	when, err := http.ParseTime(v)
+	if err != nil {
+		return 0
+	}
+
+	if d := time.Until(when); d > 0 {
+		return d
+	}
+
+	return 0
+}
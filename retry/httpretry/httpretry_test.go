@@ -0,0 +1,299 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/siderolabs/go-retry/retry"
+)
+
+func Test_DefaultRetryableStatus(t *testing.T) {
+	retryable := []int{http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+	for _, code := range retryable {
+		if !DefaultRetryableStatus(&http.Response{StatusCode: code}) {
+			t.Errorf("expected status %d to be retryable", code)
+		}
+	}
+
+	nonRetryable := []int{http.StatusOK, http.StatusNotFound, http.StatusBadRequest, http.StatusUnauthorized}
+
+	for _, code := range nonRetryable {
+		if DefaultRetryableStatus(&http.Response{StatusCode: code}) {
+			t.Errorf("expected status %d to not be retryable", code)
+		}
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter(""); d != 0 {
+		t.Errorf("expected 0 for empty header, got %s", d)
+	}
+
+	if d := parseRetryAfter("5"); d != 5*time.Second {
+		t.Errorf("expected 5s, got %s", d)
+	}
+
+	if d := parseRetryAfter("-1"); d != 0 {
+		t.Errorf("expected 0 for negative delta-seconds, got %s", d)
+	}
+
+	future := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+
+	d := parseRetryAfter(future)
+	if d <= 0 || d > 10*time.Second {
+		t.Errorf("expected ~10s for HTTP-date header, got %s", d)
+	}
+
+	past := time.Now().Add(-10 * time.Second).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(past); d != 0 {
+		t.Errorf("expected 0 for past HTTP-date header, got %s", d)
+	}
+}
+
+func newTestRetryer(base time.Duration) retry.Retryer {
+	return retry.NewRetryer(2*time.Second, retry.NewConstantTicker(&retry.Options{Base: base}), &retry.Options{})
+}
+
+func Test_ClientDo_RetriesRetryableStatus(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := New(srv.Client(), newTestRetryer(time.Millisecond))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resp, err := client.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected 2 calls, got %d", got)
+	}
+}
+
+func Test_ClientDo_NonRetryableStatusIsFatal(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := New(srv.Client(), newTestRetryer(time.Millisecond))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := client.Do(context.Background(), req); err == nil {
+		t.Fatal("expected a fatal error for a non-retryable status")
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected exactly 1 call for a non-retryable status, got %d", got)
+	}
+}
+
+func Test_ClientDo_ResetsBodyBetweenAttempts(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unexpected error reading body: %s", err)
+		}
+
+		if string(body) != "payload" {
+			t.Errorf("expected body %q, got %q", "payload", body)
+		}
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := New(srv.Client(), newTestRetryer(time.Millisecond))
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resp, err := client.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resp.Body.Close()
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected 2 calls, got %d", got)
+	}
+}
+
+type fakeTransportError struct{}
+
+func (fakeTransportError) Error() string { return "fake transport error" }
+
+// fakeTransport fails the first n requests with errFake, then delegates to
+// the wrapped RoundTripper.
+type fakeTransport struct {
+	http.RoundTripper
+
+	n     int32
+	calls int32
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&f.calls, 1) <= f.n {
+		return nil, fakeTransportError{}
+	}
+
+	return f.RoundTripper.RoundTrip(req)
+}
+
+func Test_ClientDo_WithRetryableError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	transport := &fakeTransport{RoundTripper: srv.Client().Transport, n: 1}
+	httpClient := &http.Client{Transport: transport}
+
+	client := New(httpClient, newTestRetryer(time.Millisecond), WithRetryableError(func(err error) bool {
+		var fakeErr fakeTransportError
+
+		return errors.As(err, &fakeErr)
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resp, err := client.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	defer resp.Body.Close()
+
+	if got := atomic.LoadInt32(&transport.calls); got != 2 {
+		t.Fatalf("expected 2 calls, got %d", got)
+	}
+}
+
+func Test_ClientDo_CancelDuringBackoffStopsPromptly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	// Base is much larger than this test's patience; without ctx
+	// cancellation interrupting the backoff sleep, this test would have
+	// to wait out the full 10s deadline.
+	retryer := retry.NewRetryer(10*time.Second, retry.NewConstantTicker(&retry.Options{Base: 5 * time.Second}), &retry.Options{})
+	client := New(srv.Client(), retryer)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	start := time.Now()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	if _, err := client.Do(ctx, req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("expected canceling ctx to interrupt the backoff sleep, took %s", elapsed)
+	}
+}
+
+func Test_ClientDo_RetryAfterOverridesBackoff(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// Base is much larger than the Retry-After header; without the header
+	// overriding the next tick, this test would take ~5s instead of ~1s.
+	retryer := retry.NewRetryer(10*time.Second, retry.NewConstantTicker(&retry.Options{Base: 5 * time.Second}), &retry.Options{})
+	client := New(srv.Client(), retryer)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	start := time.Now()
+
+	resp, err := client.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("expected Retry-After to override the 5s backoff, took %s", elapsed)
+	}
+}
@@ -0,0 +1,129 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// fullJitterTicker implements the "full jitter" backoff algorithm
+// described at
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/:
+// each sleep is a random duration in [0, min(Cap, Base*Multiplier^attempt)).
+//
+// Unlike the value-typed ticker, fullJitterTicker tracks the attempt count
+// across calls to Tick, so it is constructed via NewFullJitterTicker and
+// used through a pointer.
+type fullJitterTicker struct {
+	options *Options
+	rand    *rand.Rand
+	s       chan struct{}
+	attempt uint
+}
+
+// NewFullJitterTicker returns a Ticker implementing full-jitter backoff.
+func NewFullJitterTicker(options *Options) Ticker {
+	return &fullJitterTicker{
+		options: options,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		s:       make(chan struct{}),
+	}
+}
+
+// maxFullJitterBackoff bounds the backoff computed by fullJitterTicker.Tick
+// when Cap is left at its documented "unbounded" zero value. It is exactly
+// representable as a float64, so clamping to it can never overflow on the
+// conversion to int64 below. Base*Multiplier^attempt otherwise overflows
+// float64 to +Inf well within the lifetime of a long-running retry loop
+// (e.g. NewWatchingRetryer's reconciler), which would make Int63n panic.
+const maxFullJitterBackoff = float64(1 << 62)
+
+func (t *fullJitterTicker) Tick() time.Duration {
+	multiplier := t.options.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	backoff := float64(t.options.Base) * math.Pow(multiplier, float64(t.attempt))
+	if !(backoff < maxFullJitterBackoff) {
+		backoff = maxFullJitterBackoff
+	}
+
+	if t.options.Cap > 0 && backoff > float64(t.options.Cap) {
+		backoff = float64(t.options.Cap)
+	}
+
+	t.attempt++
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(t.rand.Int63n(int64(backoff)))
+}
+
+func (t *fullJitterTicker) StopChan() <-chan struct{} {
+	return t.s
+}
+
+func (t *fullJitterTicker) Stop() {
+	t.s <- struct{}{}
+}
+
+// decorrelatedJitterTicker implements the "decorrelated jitter" backoff
+// algorithm from the same source: each sleep is
+// min(Cap, random_between(Base, prev*3)), with prev initialized to Base.
+//
+// Like fullJitterTicker, it tracks state (the previous sleep) across calls
+// to Tick, so it is constructed via NewDecorrelatedJitterTicker and used
+// through a pointer.
+type decorrelatedJitterTicker struct {
+	options *Options
+	rand    *rand.Rand
+	s       chan struct{}
+	prev    time.Duration
+}
+
+// NewDecorrelatedJitterTicker returns a Ticker implementing
+// decorrelated-jitter backoff.
+func NewDecorrelatedJitterTicker(options *Options) Ticker {
+	return &decorrelatedJitterTicker{
+		options: options,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		s:       make(chan struct{}),
+		prev:    options.Base,
+	}
+}
+
+func (t *decorrelatedJitterTicker) Tick() time.Duration {
+	spread := int64(t.prev)*3 - int64(t.options.Base)
+	if spread <= 0 {
+		spread = int64(t.options.Base)
+	}
+
+	sleep := t.options.Base
+
+	if spread > 0 {
+		sleep += time.Duration(t.rand.Int63n(spread))
+	}
+
+	if t.options.Cap > 0 && sleep > t.options.Cap {
+		sleep = t.options.Cap
+	}
+
+	t.prev = sleep
+
+	return sleep
+}
+
+func (t *decorrelatedJitterTicker) StopChan() <-chan struct{} {
+	return t.s
+}
+
+func (t *decorrelatedJitterTicker) Stop() {
+	t.s <- struct{}{}
+}
@@ -0,0 +1,45 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//nolint:testpackage
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_fullJitterTicker(t *testing.T) {
+	ticker := NewFullJitterTicker(&Options{Base: time.Millisecond, Cap: 10 * time.Millisecond})
+
+	for i := 0; i < 10; i++ {
+		d := ticker.Tick()
+
+		if d < 0 || d > 10*time.Millisecond {
+			t.Fatalf("tick %d out of range: %s", i, d)
+		}
+	}
+}
+
+func Test_fullJitterTicker_UncappedDoesNotPanic(t *testing.T) {
+	ticker := NewFullJitterTicker(&Options{Base: 50 * time.Millisecond})
+
+	for i := 0; i < 1100; i++ {
+		if d := ticker.Tick(); d < 0 {
+			t.Fatalf("tick %d out of range: %s", i, d)
+		}
+	}
+}
+
+func Test_decorrelatedJitterTicker(t *testing.T) {
+	ticker := NewDecorrelatedJitterTicker(&Options{Base: time.Millisecond, Cap: 10 * time.Millisecond})
+
+	for i := 0; i < 10; i++ {
+		d := ticker.Tick()
+
+		if d < time.Millisecond || d > 10*time.Millisecond {
+			t.Fatalf("tick %d out of range: %s", i, d)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package retry
+
+import "time"
+
+// Options configures the behavior of a Retryer and its Ticker.
+type Options struct {
+	// Jitter is the maximum random jitter added on top of each tick.
+	Jitter time.Duration
+
+	// LogErrors enables logging of each unique expected error encountered
+	// while retrying.
+	LogErrors bool
+
+	// Attempts bounds the number of times the retried function is
+	// invoked, independently of the overall deadline. Zero means
+	// unlimited, preserving the previous deadline-only behavior.
+	Attempts uint
+
+	// Base is the starting backoff duration: the constant interval for
+	// NewConstantTicker, and the starting point that scales with attempt
+	// count for NewFullJitterTicker and NewDecorrelatedJitterTicker.
+	Base time.Duration
+
+	// AttemptTimeout, if non-zero, bounds each individual invocation of
+	// the retried function with its own context, independently of the
+	// overall deadline. An attempt that fails because this timeout
+	// elapsed (rather than because the caller's context was canceled) is
+	// treated as an expected, retryable condition.
+	AttemptTimeout time.Duration
+
+	// Cap bounds the duration returned by NewFullJitterTicker and
+	// NewDecorrelatedJitterTicker. Zero means unbounded.
+	Cap time.Duration
+
+	// Multiplier is the exponential growth factor used by
+	// NewFullJitterTicker. Zero defaults to 2.
+	Multiplier float64
+
+	// OnRetry, if set, is invoked after each failed attempt that will be
+	// retried, before the next sleep. attempt counts invocations of the
+	// retried function starting at 1. It is useful for instrumenting
+	// retries with metrics or tracing spans.
+	OnRetry func(attempt uint, err error)
+
+	// RetryIf, if set, is consulted for errors that are not already
+	// wrapped with ExpectedError or UnexpectedError. Returning true treats
+	// the error as expected (retryable); returning false treats it as
+	// unexpected (fatal). This centralizes retry-vs-give-up policy at the
+	// Retryer construction site instead of requiring every RetryableFunc
+	// to wrap its own errors.
+	RetryIf func(err error) bool
+}
+
+// NewDefaultOptions returns an *Options with reasonable defaults for use
+// with NewConstantTicker.
+func NewDefaultOptions() *Options {
+	return &Options{
+		Base: 50 * time.Millisecond,
+	}
+}
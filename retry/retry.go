@@ -6,6 +6,7 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -17,9 +18,21 @@ import (
 // RetryableFunc represents a function that can be retried.
 type RetryableFunc func() error
 
+// RetryableFuncWithContext represents a function that can be retried and
+// accepts a context, e.g. to honor Options.AttemptTimeout or cooperate with
+// cancellation of the overall retry.
+type RetryableFuncWithContext func(context.Context) error
+
 // Retryer defines the requirements for retrying a function.
 type Retryer interface {
 	Retry(RetryableFunc) error
+
+	// RetryWithContext is like Retry, but threads ctx through to every
+	// attempt: the retry loop observes ctx.Done() between attempts, and
+	// Options.AttemptTimeout (if set) derives each attempt's deadline
+	// from ctx instead of from context.Background(). Retry is equivalent
+	// to calling RetryWithContext(context.Background(), f).
+	RetryWithContext(context.Context, RetryableFuncWithContext) error
 }
 
 // Ticker defines the requirements for providing a clock to the retry logic.
@@ -80,7 +93,13 @@ func (e *ErrorSet) Is(err error) bool {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	return len(e.errs) == 1 && errors.Is(e.errs[0], err)
+	for _, existingErr := range e.errs {
+		if errors.Is(existingErr, err) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // TimeoutError represents a timeout error.
@@ -97,12 +116,26 @@ func IsTimeout(err error) bool {
 	return ok
 }
 
+// MaxAttemptsError represents an error when the number of attempts has
+// exceeded Options.Attempts.
+type MaxAttemptsError struct{}
+
+func (MaxAttemptsError) Error() string {
+	return "max retry attempts exceeded"
+}
+
 type expectedError struct{ error }
 
 func (e expectedError) Unwrap() error {
 	return e.error
 }
 
+type expectedErrorAfter struct {
+	expectedError
+
+	after time.Duration
+}
+
 type unexpectedError struct{ error }
 
 func (e unexpectedError) Unwrap() error {
@@ -111,9 +144,27 @@ func (e unexpectedError) Unwrap() error {
 
 type retryer struct {
 	duration time.Duration
+	ticker   Ticker
 	options  *Options
 }
 
+// NewRetryer returns a Retryer that invokes f until it succeeds, d elapses,
+// ticker's StopChan fires, or an unexpected/exhausted error occurs,
+// governed by options.
+func NewRetryer(d time.Duration, ticker Ticker, options *Options) Retryer {
+	return &retryer{duration: d, ticker: ticker, options: options}
+}
+
+func (r *retryer) Retry(f RetryableFunc) error {
+	return r.RetryWithContext(context.Background(), func(context.Context) error {
+		return f()
+	})
+}
+
+func (r *retryer) RetryWithContext(ctx context.Context, f RetryableFuncWithContext) error {
+	return retry(ctx, f, r.duration, r.ticker, r.options)
+}
+
 type ticker struct {
 	C       chan time.Time
 	options *Options
@@ -141,6 +192,25 @@ func (t ticker) Stop() {
 	t.s <- struct{}{}
 }
 
+type constantTicker struct {
+	ticker
+}
+
+// NewConstantTicker returns a Ticker that ticks every options.Base, plus
+// jitter.
+func NewConstantTicker(options *Options) Ticker {
+	return &constantTicker{
+		ticker: ticker{
+			options: options,
+			s:       make(chan struct{}),
+		},
+	}
+}
+
+func (t *constantTicker) Tick() time.Duration {
+	return t.options.Base + t.Jitter()
+}
+
 // ExpectedError error represents an error that is expected by the retrying
 // function. This error is ignored.
 func ExpectedError(err error) error {
@@ -161,37 +231,120 @@ func UnexpectedError(err error) error {
 	return unexpectedError{err}
 }
 
-func retry(f RetryableFunc, d time.Duration, t Ticker, o *Options) error {
+// ExpectedErrorAfter is like ExpectedError, but additionally tells the
+// retry loop to sleep for exactly d before the next attempt, overriding
+// whatever the configured Ticker would otherwise produce for this one
+// cycle. It is meant for callers that receive an explicit delay from the
+// thing they're retrying, e.g. a Retry-After response header.
+func ExpectedErrorAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+
+	return expectedErrorAfter{expectedError: expectedError{err}, after: d}
+}
+
+func retry(ctx context.Context, f RetryableFuncWithContext, d time.Duration, t Ticker, o *Options) error {
 	timer := time.NewTimer(d)
 	defer timer.Stop()
 
 	errs := &ErrorSet{}
 
+	var attempt uint
+
 	for {
-		if err := f(); err != nil {
+		select {
+		case <-ctx.Done():
+			errs.Append(ctx.Err())
+
+			return errs
+		default:
+		}
+
+		attemptCtx := ctx
+
+		var cancel context.CancelFunc
+
+		if o.AttemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, o.AttemptTimeout)
+		}
+
+		err := f(attemptCtx)
+
+		if cancel != nil {
+			cancel()
+		}
+
+		nextTick := time.Duration(-1)
+
+		if err != nil {
+			attempt++
+
 			exists := errs.Append(err)
 
-			switch err.(type) {
+			retryable := false
+
+			switch e := err.(type) {
+			case expectedErrorAfter:
+				// retry expected errors, sleeping for the caller-specified
+				// duration instead of the Ticker's own next tick
+				retryable = true
+				nextTick = e.after
 			case expectedError:
 				// retry expected errors
-				if !exists && o.LogErrors {
-					log.Printf("retrying error: %s", err)
-				}
+				retryable = true
+			case unexpectedError:
+				// never retry errors the caller has explicitly marked fatal,
+				// regardless of what RetryIf would say about the unwrapped error
+				retryable = false
 			default:
+				if attemptCtx.Err() != nil && ctx.Err() == nil {
+					// f returned because its own per-attempt deadline elapsed,
+					// not because the caller's context was canceled; that's an
+					// expected, retryable condition.
+					retryable = true
+				} else {
+					retryable = o.RetryIf != nil && o.RetryIf(err)
+				}
+			}
+
+			if !retryable {
+				return errs
+			}
+
+			if o.Attempts > 0 && attempt >= o.Attempts {
+				errs.Append(MaxAttemptsError{})
+
 				return errs
 			}
+
+			if !exists && o.LogErrors {
+				log.Printf("retrying error: %s", err)
+			}
+
+			if o.OnRetry != nil {
+				o.OnRetry(attempt, err)
+			}
 		} else {
 			return nil
 		}
 
+		if nextTick < 0 {
+			nextTick = t.Tick()
+		}
+
 		select {
+		case <-ctx.Done():
+			errs.Append(ctx.Err())
+
+			return errs
 		case <-timer.C:
 			errs.Append(TimeoutError{})
 
 			return errs
 		case <-t.StopChan():
 			return nil
-		case <-time.After(t.Tick()):
+		case <-time.After(nextTick):
 		}
 	}
 }
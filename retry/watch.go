@@ -0,0 +1,165 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package retry
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// WatchFunc is run whenever the channel associated with a Watch produces a
+// value.
+type WatchFunc func(context.Context) error
+
+// Watch bundles a named channel with the function to run under the base
+// retry policy whenever that channel fires. Channel must be a receivable
+// channel type (e.g. <-chan T); it is consumed via reflection so that
+// watches of different element types can be mixed in a single
+// NewWatchingRetryer call.
+type Watch struct {
+	Name    string
+	Channel any
+	Func    WatchFunc
+}
+
+// WatchOption configures a Retryer returned by NewWatchingRetryer.
+type WatchOption func(*watchingRetryer)
+
+// WithMaxInterval caps the backoff interval used while retrying reactive
+// handlers, independently of the base Retryer's own policy.
+func WithMaxInterval(d time.Duration) WatchOption {
+	return func(r *watchingRetryer) {
+		r.maxInterval = d
+	}
+}
+
+type watchingRetryer struct {
+	ctx         context.Context //nolint:containedctx
+	base        Retryer
+	watches     []Watch
+	maxInterval time.Duration
+}
+
+// NewWatchingRetryer returns a Retryer that first runs the primary function
+// under base's retry policy, then reacts to any of watches firing by
+// re-running the associated WatchFunc, also under base's retry policy.
+// It is intended for long-running reconcilers that need to react to
+// config-change or invalidation events without writing a bespoke select
+// loop.
+//
+// Retry does not return until ctx is canceled or a handler returns a
+// fatal error; callers that need to stop reacting to watches should
+// cancel ctx. A watched channel being closed does not end the watch loop
+// on its own: the channel is simply dropped from future selects, and once
+// every watch has closed, Retry just blocks until ctx is canceled.
+func NewWatchingRetryer(ctx context.Context, base Retryer, watches []Watch, opts ...WatchOption) Retryer {
+	r := &watchingRetryer{
+		ctx:     ctx,
+		base:    base,
+		watches: watches,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+func (r *watchingRetryer) Retry(f RetryableFunc) error {
+	if err := r.base.RetryWithContext(r.ctx, func(context.Context) error {
+		return f()
+	}); err != nil {
+		return err
+	}
+
+	return r.watch(r.ctx)
+}
+
+// RetryWithContext is like Retry, but ctx (rather than the context given
+// to NewWatchingRetryer) governs both the primary attempt and the
+// subsequent watch loop for this call.
+func (r *watchingRetryer) RetryWithContext(ctx context.Context, f RetryableFuncWithContext) error {
+	if err := r.base.RetryWithContext(ctx, f); err != nil {
+		return err
+	}
+
+	return r.watch(ctx)
+}
+
+func (r *watchingRetryer) watch(ctx context.Context) error {
+	cases := make([]reflect.SelectCase, 0, len(r.watches)+1)
+
+	cases = append(cases, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ctx.Done()),
+	})
+
+	for _, w := range r.watches {
+		cases = append(cases, reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(w.Channel),
+		})
+	}
+
+	for {
+		chosen, _, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return ctx.Err()
+		}
+
+		if !ok {
+			// the channel was closed; stop watching it by never selecting
+			// it again.
+			cases[chosen].Chan = reflect.Value{}
+
+			continue
+		}
+
+		w := r.watches[chosen-1]
+
+		if err := r.runHandler(ctx, w); err != nil {
+			return err
+		}
+	}
+}
+
+// runHandler retries w.Func under the base policy. When maxInterval is set
+// and base was constructed with NewRetryer, the handler is retried through
+// a clone of base whose Ticker is wrapped to cap each tick at maxInterval;
+// RetryIf, OnRetry and Attempts all still come from base's own Options, so
+// only the backoff interval differs from the primary retry policy.
+func (r *watchingRetryer) runHandler(ctx context.Context, w Watch) error {
+	handlerRetryer := r.base
+
+	if r.maxInterval > 0 {
+		if br, ok := r.base.(*retryer); ok {
+			handlerRetryer = &retryer{
+				duration: br.duration,
+				ticker:   cappedTicker{Ticker: br.ticker, cap: r.maxInterval},
+				options:  br.options,
+			}
+		}
+	}
+
+	return handlerRetryer.RetryWithContext(ctx, RetryableFuncWithContext(w.Func))
+}
+
+// cappedTicker wraps a Ticker, bounding the duration it produces. It lets
+// runHandler reuse a base Retryer's own Ticker and Options in full while
+// still bounding the backoff applied to reactive handlers.
+type cappedTicker struct {
+	Ticker
+	cap time.Duration
+}
+
+func (t cappedTicker) Tick() time.Duration {
+	if d := t.Ticker.Tick(); d < t.cap {
+		return d
+	}
+
+	return t.cap
+}
@@ -0,0 +1,140 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//nolint:testpackage
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_WatchingRetryer(t *testing.T) {
+	primary := NewRetryer(time.Second, NewConstantTicker(NewDefaultOptions()), &Options{})
+
+	ch := make(chan struct{}, 1)
+
+	var fired int
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := NewWatchingRetryer(ctx, primary, []Watch{
+		{
+			Name:    "signal",
+			Channel: (<-chan struct{})(ch),
+			Func: func(context.Context) error {
+				fired++
+				cancel()
+
+				return nil
+			},
+		},
+	})
+
+	ch <- struct{}{}
+
+	if err := r.Retry(func() error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if fired != 1 {
+		t.Fatalf("expected handler to fire once, got %d", fired)
+	}
+}
+
+func Test_WatchingRetryer_CanceledDuringPrimary(t *testing.T) {
+	primary := NewRetryer(time.Second, NewConstantTicker(&Options{Base: time.Second}), &Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := NewWatchingRetryer(ctx, primary, nil)
+
+	cancel()
+
+	// primary is never going to succeed on its own, and its 1s tick would
+	// normally keep it retrying well past this test's patience; canceling
+	// ctx before the primary function is even reached should still make
+	// Retry return promptly.
+	start := time.Now()
+
+	err := r.Retry(func() error { return ExpectedError(errors.New("not yet")) })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected canceled ctx to stop the primary retry immediately, took %s", elapsed)
+	}
+}
+
+func Test_WatchingRetryer_RetryWithContextGovernsWatchPhase(t *testing.T) {
+	primary := NewRetryer(time.Second, NewConstantTicker(NewDefaultOptions()), &Options{})
+
+	// constructorCtx deliberately outlives the call: RetryWithContext's own
+	// ctx, not the constructor's, should govern this call's watch phase.
+	constructorCtx, constructorCancel := context.WithCancel(context.Background())
+	defer constructorCancel()
+
+	r := NewWatchingRetryer(constructorCtx, primary, nil)
+
+	callCtx, callCancel := context.WithCancel(context.Background())
+	callCancel()
+
+	start := time.Now()
+
+	err := r.RetryWithContext(callCtx, func(context.Context) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the call's own ctx to end the watch phase immediately, took %s", elapsed)
+	}
+}
+
+func Test_WatchingRetryer_MaxInterval(t *testing.T) {
+	primary := NewRetryer(time.Second, NewConstantTicker(&Options{Base: time.Second}), &Options{})
+
+	ch := make(chan struct{}, 1)
+
+	var calls int
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := NewWatchingRetryer(ctx, primary, []Watch{
+		{
+			Name:    "signal",
+			Channel: (<-chan struct{})(ch),
+			Func: func(context.Context) error {
+				calls++
+
+				if calls < 2 {
+					return ExpectedError(errors.New("not yet"))
+				}
+
+				cancel()
+
+				return nil
+			},
+		},
+	}, WithMaxInterval(time.Millisecond))
+
+	ch <- struct{}{}
+
+	start := time.Now()
+
+	if err := r.Retry(func() error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// without the cap, the retry would wait out primary's 1s tick; with
+	// WithMaxInterval(time.Millisecond) it should complete quickly.
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected capped backoff to keep this fast, took %s", elapsed)
+	}
+}